@@ -9,9 +9,13 @@ import (
 
 var (
 	// election-id (max 128 bytes) + base64-of-a-8096-bit-public-key + SHA512-BallotID + (64 vote preferences) + (64 tags) + signature + line-seperators
+	//
+	// Sized for the largest supported scheme (RSA-PSS-SHA512); an Ed25519 ballot is a
+	// small fraction of this because its keys, BallotID digest, and signature are all
+	// much shorter.
 	maxTagKeySize   = 64
 	maxTagValueSize = 256
-	maxBallotSize   = (128) + (1352) + (128) + (64 * 256 * 2) + (64 * (maxTagKeySize + maxTagValueSize + 1)) + (128 + (172)) + (18 + 64 + 64)
+	maxBallotSize   = (128) + (1352) + (128) + (64 * 256 * 2) + (64 * (maxTagKeySize + maxTagValueSize + 1)) + (128 + (172)) + (18 + 64 + 64) + (maxEndorsements * (1352 + 172))
 )
 
 type Ballot struct {
@@ -20,22 +24,35 @@ type Ballot struct {
 	PublicKey  // base64 encoded PEM formatted public-key
 	Vote       // Ordered list of choices
 	TagSet
-	Signature // Crypto signature for the ballot
+	Signature                   // Crypto signature for the ballot
+	Endorsements EndorsementSet // Optional k-of-n ballotclerk co-signatures
 }
 
-func NewBallot(rawBallot []byte) (Ballot, error) {
+func NewBallot(rawBallot []byte, election *Election) (Ballot, error) {
 	var (
-		hasTags    bool
-		err        error
-		electionID string
-		ballotID   BallotID
-		publicKey  PublicKey
-		vote       Vote
-		tagSet     TagSet
-		signature  Signature
+		hasTags      bool
+		err          error
+		electionID   string
+		ballotID     BallotID
+		publicKey    PublicKey
+		vote         Vote
+		tagSet       TagSet
+		signature    Signature
+		endorsements EndorsementSet
 	)
 
-	parts := bytes.Split(rawBallot, []byte("\n\n"))
+	// The endorsements block, if present, is appended after the rest of the ballot
+	// separated by a triple newline, so its presence doesn't disturb the "\n\n"-split
+	// parsing of the core ballot fields below.
+	coreAndEndorsements := bytes.SplitN(rawBallot, []byte("\n\n\n"), 2)
+	if len(coreAndEndorsements) == 2 {
+		endorsements, err = NewEndorsementSet(coreAndEndorsements[1])
+		if err != nil {
+			return Ballot{}, err
+		}
+	}
+
+	parts := bytes.Split(coreAndEndorsements[0], []byte("\n\n"))
 
 	if len(parts) == 5 {
 		hasTags = false
@@ -47,17 +64,17 @@ func NewBallot(rawBallot []byte) (Ballot, error) {
 
 	electionID = string(parts[0])
 
-	ballotID, err = NewBallotID(parts[1])
+	publicKey, err = NewPublicKey(parts[2])
 	if err != nil {
 		return Ballot{}, err
 	}
 
-	publicKey, err = NewPublicKey(parts[2])
+	ballotID, err = NewBallotID(parts[1], publicKey.Algorithm)
 	if err != nil {
 		return Ballot{}, err
 	}
 
-	vote, err = NewVote(parts[3])
+	vote, err = NewVote(parts[3], election)
 	if err != nil {
 		return Ballot{}, err
 	}
@@ -67,8 +84,12 @@ func NewBallot(rawBallot []byte) (Ballot, error) {
 		if err != nil {
 			return Ballot{}, err
 		}
-	} else {
-		tagSet = nil
+	}
+
+	if election != nil && election.TagSchema != nil {
+		if err = election.TagSchema.Validate(tagSet); err != nil {
+			return Ballot{}, err
+		}
 	}
 
 	if hasTags {
@@ -87,6 +108,7 @@ func NewBallot(rawBallot []byte) (Ballot, error) {
 		vote,
 		tagSet,
 		signature,
+		endorsements,
 	}
 
 	// Verify the signature
@@ -116,23 +138,34 @@ func (ballot Ballot) String() string {
 		ballot.BallotID.String(),
 		ballot.PublicKey.String(),
 		ballot.Vote.String(),
-		ballot.TagSet.String(),
-		ballot.Signature.String(),
 	}
-	return strings.Join(s, "\n\n")
+	// The tags field is only present in the text format when the ballot actually
+	// carries tags, mirroring the hasTags branch NewBallot parses against; an empty
+	// TagSet still contributes to VerifySignature's payload, just not to this text.
+	if tags := ballot.TagSet.String(); tags != "" {
+		s = append(s, tags)
+	}
+	s = append(s, ballot.Signature.String())
+	core := strings.Join(s, "\n\n")
+	if len(ballot.Endorsements) == 0 {
+		return core
+	}
+	return core + "\n\n\n" + ballot.Endorsements.String()
 }
 
 type BallotID []byte
 
-// Given a string, return a new BallotID object.
-// This function also performs error checking to make sure the BallotID is 128 characters long and base64 encoded
-func NewBallotID(rawBallotID []byte) (BallotID, error) {
-	// SHA512 is 128 characters long and is a valid hex
-	if len(rawBallotID) != 128 {
-		return nil, errors.New("Ballot ID must be 128 characters long. It is the SHA512 of the base64 encoded public key.")
+// Given a string and the negotiated signature algorithm, return a new BallotID object.
+// This function also performs error checking to make sure the BallotID is hex encoded
+// and the correct length for the digest used by alg (SHA512 for RSA schemes, SHA256 for
+// Ed25519).
+func NewBallotID(rawBallotID []byte, alg SignatureAlgorithm) (BallotID, error) {
+	wantLen := digestSize(alg) * 2 // hex encoding doubles the byte length
+	if len(rawBallotID) != wantLen {
+		return nil, errors.New("Ballot ID must be a hex encoded digest of the base64 encoded public key")
 	}
 	if _, err := hex.Decode(make([]byte, hex.DecodedLen(len(rawBallotID))), rawBallotID); err != nil {
-		return nil, errors.New("Ballot ID must be hex encoded. It is the SHA512 of the base64 encoded public key.")
+		return nil, errors.New("Ballot ID must be hex encoded. It is a digest of the base64 encoded public key.")
 	}
 	return BallotID(rawBallotID), nil
 }
@@ -140,73 +173,3 @@ func NewBallotID(rawBallotID []byte) (BallotID, error) {
 func (ballotID BallotID) String() string {
 	return string(ballotID)
 }
-
-type Vote [][]byte // Ordered list of choices represented by git addresses
-
-func NewVote(rawVote []byte) (Vote, error) {
-	return Vote(bytes.Split(rawVote, []byte("\n"))), nil
-}
-
-func (vote Vote) String() string {
-	var output string
-	for i, voteItem := range vote {
-		output += string(voteItem)
-		if i != len(vote)-1 {
-			output += "\n"
-		}
-	}
-	return output
-}
-
-type Tag struct {
-	Key   []byte
-	Value []byte
-}
-
-func NewTag(rawTag []byte) (Tag, error) {
-	parts := bytes.SplitN(rawTag, []byte("="), 2)
-	if len(parts) != 2 {
-		return Tag{}, errors.New("Malformed tag")
-	}
-	if len(parts[0]) > maxTagKeySize {
-		return Tag{}, errors.New("Tag key too long")
-	}
-	if len(parts[1]) > maxTagValueSize {
-		return Tag{}, errors.New("Tag value too long")
-	}
-
-	return Tag{
-		parts[0],
-		parts[1],
-	}, nil
-}
-
-func (tag Tag) String() string {
-	return string(tag.Key) + "=" + string(tag.Value)
-}
-
-type TagSet []Tag
-
-func NewTagSet(rawTagSet []byte) (TagSet, error) {
-	parts := bytes.Split(rawTagSet, []byte("\n"))
-	tagSet := TagSet(make([]Tag, len(parts)))
-	for i, rawTag := range parts {
-		tag, err := NewTag(rawTag)
-		if err != nil {
-			return TagSet{}, err
-		}
-		tagSet[i] = tag
-	}
-	return tagSet, nil
-}
-
-func (tagSet TagSet) String() string {
-	var output string
-	for i, tag := range tagSet {
-		output += tag.String()
-		if i != len(tagSet)-1 {
-			output += "\n"
-		}
-	}
-	return output
-}
\ No newline at end of file
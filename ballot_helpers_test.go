@@ -0,0 +1,77 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"strings"
+	"testing"
+)
+
+// newTestClerk builds an Ed25519 keypair and the PublicKey a ballot/endorsement
+// field would carry for it.
+func newTestClerk(t *testing.T) (PublicKey, ed25519.PrivateKey) {
+	t.Helper()
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "ED25519 PUBLIC KEY", Bytes: pub})
+	field := []byte(base64.StdEncoding.EncodeToString(pemBytes))
+
+	signer, err := NewPublicKey(field)
+	if err != nil {
+		t.Fatalf("NewPublicKey: %v", err)
+	}
+	return signer, priv
+}
+
+// newTestEd25519Ballot builds and signs a text-form ballot using a fresh Ed25519
+// keypair. tags may be empty, in which case the ballot has no tags block.
+func newTestEd25519Ballot(t *testing.T, electionID, vote, tags string) []byte {
+	t.Helper()
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "ED25519 PUBLIC KEY", Bytes: pub})
+	pubField := base64.StdEncoding.EncodeToString(pemBytes)
+
+	ballotIDBytes := make([]byte, sha256DigestSize)
+	if _, err := rand.Read(ballotIDBytes); err != nil {
+		t.Fatalf("rand.Read: %v", err)
+	}
+	ballotIDHex := hex.EncodeToString(ballotIDBytes)
+
+	// VerifySignature always signs over five \n\n-joined fields, including an empty
+	// TagSet.String() when the ballot carries no tags, even though the text form
+	// below omits the tags field entirely in that case.
+	payload := strings.Join([]string{electionID, ballotIDHex, pubField, vote, tags}, "\n\n")
+	sig := ed25519.Sign(priv, []byte(payload))
+
+	fields := []string{electionID, ballotIDHex, pubField, vote}
+	if tags != "" {
+		fields = append(fields, tags)
+	}
+	fields = append(fields, hex.EncodeToString(sig))
+
+	return []byte(strings.Join(fields, "\n\n"))
+}
+
+// signedPayload reproduces the canonical bytes VerifySignature/VerifyEndorsements
+// sign over for ballot.
+func signedPayload(ballot Ballot) []byte {
+	return []byte(strings.Join([]string{
+		ballot.ElectionID,
+		ballot.BallotID.String(),
+		ballot.PublicKey.String(),
+		ballot.Vote.String(),
+		ballot.TagSet.String(),
+	}, "\n\n"))
+}
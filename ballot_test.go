@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"strings"
+	"testing"
+)
+
+func TestNewBallotRoundTrip(t *testing.T) {
+	raw := newTestEd25519Ballot(t, "election-1", "choice-a", "")
+
+	ballot, err := NewBallot(raw, nil)
+	if err != nil {
+		t.Fatalf("NewBallot: %v", err)
+	}
+	if got := ballot.String(); got != string(raw) {
+		t.Fatalf("round-trip mismatch:\n got: %q\nwant: %q", got, raw)
+	}
+}
+
+func TestNewBallotRoundTripWithTags(t *testing.T) {
+	raw := newTestEd25519Ballot(t, "election-1", "choice-a", "region=west")
+
+	ballot, err := NewBallot(raw, nil)
+	if err != nil {
+		t.Fatalf("NewBallot: %v", err)
+	}
+	if got := ballot.String(); got != string(raw) {
+		t.Fatalf("round-trip mismatch:\n got: %q\nwant: %q", got, raw)
+	}
+}
+
+func TestNewBallotRejectsTamperedVote(t *testing.T) {
+	raw := newTestEd25519Ballot(t, "election-1", "choice-a", "")
+	tampered := bytes.Replace(raw, []byte("choice-a"), []byte("choice-b"), 1)
+
+	if _, err := NewBallot(tampered, nil); err == nil {
+		t.Fatalf("expected signature verification to fail for tampered ballot")
+	}
+}
+
+// TestNewBallotRejectsVoteWithTrailingNewline guards against a validly-signed vote
+// ending in "\n" (an empty trailing vote item): it makes Vote.String() produce a
+// "\n\n\n" at the Vote/Signature boundary that is indistinguishable from the
+// endorsements separator. NewBallot splits there, so the ballot is rejected when
+// the trailing signature fails to parse as an endorsement block.
+func TestNewBallotRejectsVoteWithTrailingNewline(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "ED25519 PUBLIC KEY", Bytes: pub})
+	pubField := base64.StdEncoding.EncodeToString(pemBytes)
+
+	ballotIDBytes := make([]byte, sha256DigestSize)
+	if _, err := rand.Read(ballotIDBytes); err != nil {
+		t.Fatalf("rand.Read: %v", err)
+	}
+	ballotIDHex := hex.EncodeToString(ballotIDBytes)
+
+	vote := "choice-a\n" // trailing newline: an empty vote item
+	fields := []string{"election-1", ballotIDHex, pubField, vote, ""}
+	payload := strings.Join(fields, "\n\n")
+	sig := ed25519.Sign(priv, []byte(payload))
+
+	raw := []byte(strings.Join([]string{"election-1", ballotIDHex, pubField, vote, hex.EncodeToString(sig)}, "\n\n"))
+
+	if _, err := NewBallot(raw, nil); err == nil {
+		t.Fatalf("expected empty vote item to be rejected")
+	}
+}
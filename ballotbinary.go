@@ -0,0 +1,281 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"io"
+	"sort"
+)
+
+// ballotBinaryMagic and ballotBinaryVersion identify the TLV binary ballot framing
+// used by MarshalBinary/NewBallotBinary. The text form produced by Ballot.String()
+// remains the canonical payload that gets signed; this is purely a storage/wire
+// encoding, roughly a third the size and immune to newlines embedded in tag values.
+var ballotBinaryMagic = [4]byte{'C', 'B', 'A', 'L'}
+
+const ballotBinaryVersion = 1
+
+// MarshalBinary encodes the ballot as: magic, version, then a uvarint-length-prefixed
+// field for ElectionID, BallotID (raw digest bytes, not hex), an algorithm byte plus
+// the public-key's raw DER (not PEM/base64), the Vote items, the TagSet key/value
+// pairs, the Signature, and finally any Endorsements (each itself an algorithm byte
+// plus DER signer key, followed by its signature).
+func (ballot Ballot) MarshalBinary() ([]byte, error) {
+	rawBallotID, err := hex.DecodeString(string(ballot.BallotID))
+	if err != nil {
+		return nil, errors.New("Cannot marshal ballot. BallotID is not valid hex")
+	}
+
+	var buf bytes.Buffer
+	buf.Write(ballotBinaryMagic[:])
+	buf.WriteByte(ballotBinaryVersion)
+
+	writeBytes(&buf, []byte(ballot.ElectionID))
+	writeBytes(&buf, rawBallotID)
+
+	if err := writePublicKey(&buf, ballot.PublicKey); err != nil {
+		return nil, err
+	}
+
+	writeUvarint(&buf, uint64(len(ballot.Vote)))
+	for _, item := range ballot.Vote {
+		writeBytes(&buf, item)
+	}
+
+	writeUvarint(&buf, uint64(len(ballot.TagSet.tags)))
+	for _, tag := range ballot.TagSet.tags {
+		writeBytes(&buf, tag.Key)
+		writeBytes(&buf, tag.Value)
+	}
+
+	writeBytes(&buf, ballot.Signature)
+
+	writeUvarint(&buf, uint64(len(ballot.Endorsements)))
+	for _, endorsement := range ballot.Endorsements {
+		if err := writePublicKey(&buf, endorsement.Signer); err != nil {
+			return nil, err
+		}
+		writeBytes(&buf, endorsement.Signature)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// NewBallotBinary parses a ballot encoded by MarshalBinary and verifies its
+// signature, just as NewBallot does for the text format.
+func NewBallotBinary(rawBallot []byte, election *Election) (Ballot, error) {
+	r := bytes.NewReader(rawBallot)
+
+	magic := make([]byte, len(ballotBinaryMagic))
+	if _, err := io.ReadFull(r, magic); err != nil || !bytes.Equal(magic, ballotBinaryMagic[:]) {
+		return Ballot{}, errors.New("Cannot read ballot. Not a recognised binary ballot")
+	}
+
+	version, err := r.ReadByte()
+	if err != nil || version != ballotBinaryVersion {
+		return Ballot{}, errors.New("Cannot read ballot. Unsupported binary ballot version")
+	}
+
+	rawElectionID, err := readBytes(r)
+	if err != nil {
+		return Ballot{}, err
+	}
+
+	rawBallotDigest, err := readBytes(r)
+	if err != nil {
+		return Ballot{}, err
+	}
+
+	publicKey, err := readPublicKey(r)
+	if err != nil {
+		return Ballot{}, err
+	}
+
+	ballotID, err := NewBallotID([]byte(hex.EncodeToString(rawBallotDigest)), publicKey.Algorithm)
+	if err != nil {
+		return Ballot{}, err
+	}
+
+	voteCount, err := binary.ReadUvarint(r)
+	if err != nil {
+		return Ballot{}, errors.New("Cannot read ballot. Truncated binary ballot")
+	}
+	if voteCount > uint64(r.Len()) {
+		return Ballot{}, errors.New("Cannot read ballot. Truncated binary ballot")
+	}
+	vote := make(Vote, voteCount)
+	for i := range vote {
+		item, err := readBytes(r)
+		if err != nil {
+			return Ballot{}, err
+		}
+		vote[i] = item
+	}
+	if err := validateVote(vote, election); err != nil {
+		return Ballot{}, err
+	}
+
+	tagCount, err := binary.ReadUvarint(r)
+	if err != nil {
+		return Ballot{}, errors.New("Cannot read ballot. Truncated binary ballot")
+	}
+	if tagCount > uint64(r.Len()) {
+		return Ballot{}, errors.New("Cannot read ballot. Truncated binary ballot")
+	}
+	var tagSet TagSet
+	for i := uint64(0); i < tagCount; i++ {
+		key, err := readBytes(r)
+		if err != nil {
+			return Ballot{}, err
+		}
+		val, err := readBytes(r)
+		if err != nil {
+			return Ballot{}, err
+		}
+		tag, err := newTag(key, val)
+		if err != nil {
+			return Ballot{}, err
+		}
+		if err := tagSet.add(tag); err != nil {
+			return Ballot{}, err
+		}
+	}
+
+	if election != nil && election.TagSchema != nil {
+		if err := election.TagSchema.Validate(tagSet); err != nil {
+			return Ballot{}, err
+		}
+	}
+
+	rawSignature, err := readBytes(r)
+	if err != nil {
+		return Ballot{}, err
+	}
+
+	endorsementCount, err := binary.ReadUvarint(r)
+	if err != nil {
+		return Ballot{}, errors.New("Cannot read ballot. Truncated binary ballot")
+	}
+	if endorsementCount > maxEndorsements {
+		return Ballot{}, errors.New("Too many endorsements")
+	}
+	if endorsementCount > uint64(r.Len()) {
+		return Ballot{}, errors.New("Cannot read ballot. Truncated binary ballot")
+	}
+	var endorsements EndorsementSet
+	if endorsementCount > 0 {
+		endorsements = make(EndorsementSet, endorsementCount)
+		for i := range endorsements {
+			signer, err := readPublicKey(r)
+			if err != nil {
+				return Ballot{}, err
+			}
+			rawEndorsementSig, err := readBytes(r)
+			if err != nil {
+				return Ballot{}, err
+			}
+			endorsements[i] = Endorsement{signer, Signature(rawEndorsementSig)}
+		}
+		sort.Slice(endorsements, func(i, j int) bool {
+			return endorsements[i].Signer.hash() < endorsements[j].Signer.hash()
+		})
+	}
+
+	ballot := Ballot{
+		string(rawElectionID),
+		ballotID,
+		publicKey,
+		vote,
+		tagSet,
+		Signature(rawSignature),
+		endorsements,
+	}
+
+	if err := ballot.VerifySignature(); err != nil {
+		return Ballot{}, err
+	}
+
+	return ballot, nil
+}
+
+// writePublicKey encodes pub as an algorithm byte followed by its raw DER bytes.
+func writePublicKey(buf *bytes.Buffer, pub PublicKey) error {
+	pemBytes, err := base64.StdEncoding.DecodeString(string(pub.raw))
+	if err != nil {
+		return errors.New("Cannot marshal ballot. Invalid public-key")
+	}
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return errors.New("Cannot marshal ballot. Invalid public-key")
+	}
+	buf.WriteByte(byte(pub.Algorithm))
+	writeBytes(buf, block.Bytes)
+	return nil
+}
+
+// readPublicKey reads a public-key written by writePublicKey and re-derives its
+// base64(PEM) form so it round-trips through the same validation as NewPublicKey.
+func readPublicKey(r *bytes.Reader) (PublicKey, error) {
+	algByte, err := r.ReadByte()
+	if err != nil {
+		return PublicKey{}, errors.New("Cannot read ballot. Truncated binary ballot")
+	}
+	der, err := readBytes(r)
+	if err != nil {
+		return PublicKey{}, err
+	}
+	pemBytes, err := encodePublicKeyPEM(SignatureAlgorithm(algByte), der)
+	if err != nil {
+		return PublicKey{}, err
+	}
+	rawField := []byte(base64.StdEncoding.EncodeToString(pemBytes))
+	return NewPublicKey(rawField)
+}
+
+func encodePublicKeyPEM(alg SignatureAlgorithm, der []byte) ([]byte, error) {
+	var blockType string
+	switch alg {
+	case RSAPKCS1v15SHA256:
+		blockType = "PUBLIC KEY"
+	case RSAPSSSHA512:
+		blockType = "RSA-PSS PUBLIC KEY"
+	case Ed25519:
+		blockType = "ED25519 PUBLIC KEY"
+	default:
+		return nil, errors.New("Cannot read ballot. Unsupported signature algorithm")
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), nil
+}
+
+func writeUvarint(buf *bytes.Buffer, n uint64) {
+	var tmp [binary.MaxVarintLen64]byte
+	length := binary.PutUvarint(tmp[:], n)
+	buf.Write(tmp[:length])
+}
+
+func writeBytes(buf *bytes.Buffer, b []byte) {
+	writeUvarint(buf, uint64(len(b)))
+	buf.Write(b)
+}
+
+func readBytes(r *bytes.Reader) ([]byte, error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, errors.New("Cannot read ballot. Truncated binary ballot")
+	}
+	// n is attacker-controlled: reject it against the bytes actually left in r before
+	// allocating, rather than letting a single huge length crash the process with an
+	// out-of-memory make([]byte, n).
+	if n > uint64(r.Len()) {
+		return nil, errors.New("Cannot read ballot. Truncated binary ballot")
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, errors.New("Cannot read ballot. Truncated binary ballot")
+	}
+	return b, nil
+}
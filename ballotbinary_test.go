@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"testing"
+)
+
+func TestBallotBinaryRoundTrip(t *testing.T) {
+	raw := newTestEd25519Ballot(t, "election-1", "choice-a", "region=west")
+
+	ballot, err := NewBallot(raw, nil)
+	if err != nil {
+		t.Fatalf("NewBallot: %v", err)
+	}
+
+	encoded, err := ballot.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary: %v", err)
+	}
+
+	decoded, err := NewBallotBinary(encoded, nil)
+	if err != nil {
+		t.Fatalf("NewBallotBinary: %v", err)
+	}
+
+	if decoded.String() != ballot.String() {
+		t.Fatalf("binary round-trip mismatch:\n got: %q\nwant: %q", decoded.String(), ballot.String())
+	}
+}
+
+func TestBallotBinaryRoundTripWithEndorsements(t *testing.T) {
+	raw := newTestEd25519Ballot(t, "election-1", "choice-a", "")
+	ballot, err := NewBallot(raw, nil)
+	if err != nil {
+		t.Fatalf("NewBallot: %v", err)
+	}
+
+	signer, priv := newTestClerk(t)
+	sig := ed25519.Sign(priv, signedPayload(ballot))
+	ballot.Endorsements = EndorsementSet{{signer, Signature(sig)}}
+
+	encoded, err := ballot.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary: %v", err)
+	}
+
+	decoded, err := NewBallotBinary(encoded, nil)
+	if err != nil {
+		t.Fatalf("NewBallotBinary: %v", err)
+	}
+
+	clerks := UserSet{signer.hash(): signer}
+	if err := decoded.VerifyEndorsements(clerks, 1); err != nil {
+		t.Fatalf("VerifyEndorsements: %v", err)
+	}
+}
+
+func TestNewBallotBinaryRejectsBadMagic(t *testing.T) {
+	_, err := NewBallotBinary([]byte("not a ballot"), nil)
+	if err == nil {
+		t.Fatalf("expected error for unrecognised binary ballot")
+	}
+}
+
+func TestNewBallotBinaryRejectsTruncated(t *testing.T) {
+	raw := newTestEd25519Ballot(t, "election-1", "choice-a", "")
+	ballot, err := NewBallot(raw, nil)
+	if err != nil {
+		t.Fatalf("NewBallot: %v", err)
+	}
+
+	encoded, err := ballot.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary: %v", err)
+	}
+
+	if _, err := NewBallotBinary(encoded[:len(encoded)-4], nil); err == nil {
+		t.Fatalf("expected error for truncated binary ballot")
+	}
+}
+
+// TestNewBallotBinaryRejectsOversizedLength guards against a crafted length prefix
+// that claims far more data than is actually present, which previously reached
+// make([]byte, n)/make(Vote, n) unchecked and could crash the process.
+func TestNewBallotBinaryRejectsOversizedLength(t *testing.T) {
+	var buf bytes.Buffer
+	buf.Write(ballotBinaryMagic[:])
+	buf.WriteByte(ballotBinaryVersion)
+	writeUvarint(&buf, 1<<40)
+
+	if _, err := NewBallotBinary(buf.Bytes(), nil); err == nil {
+		t.Fatalf("expected error for an oversized length prefix")
+	}
+}
+
+func TestNewBallotBinaryRejectsTooManyEndorsements(t *testing.T) {
+	raw := newTestEd25519Ballot(t, "election-1", "choice-a", "")
+	ballot, err := NewBallot(raw, nil)
+	if err != nil {
+		t.Fatalf("NewBallot: %v", err)
+	}
+
+	signer, priv := newTestClerk(t)
+	sig := ed25519.Sign(priv, signedPayload(ballot))
+	for i := 0; i <= maxEndorsements; i++ {
+		ballot.Endorsements = append(ballot.Endorsements, Endorsement{signer, Signature(sig)})
+	}
+
+	encoded, err := ballot.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary: %v", err)
+	}
+
+	if _, err := NewBallotBinary(encoded, nil); err == nil {
+		t.Fatalf("expected error for exceeding maxEndorsements")
+	}
+}
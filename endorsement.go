@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+	"strings"
+)
+
+// maxEndorsements bounds the number of ballotclerk co-signatures a single ballot may
+// carry, so a malicious client can't inflate a ballot with endorsements that will
+// never be counted toward any realistic threshold.
+const maxEndorsements = 16
+
+// Endorsement is a single ballotclerk's co-signature over a ballot's canonical
+// ElectionID||BallotID||PublicKey||Vote||TagSet bytes.
+type Endorsement struct {
+	Signer    PublicKey
+	Signature Signature
+}
+
+func NewEndorsement(rawEndorsement []byte) (Endorsement, error) {
+	parts := bytes.SplitN(rawEndorsement, []byte("\n\n"), 2)
+	if len(parts) != 2 {
+		return Endorsement{}, errors.New("Cannot read endorsement. Invalid endorsement format")
+	}
+
+	signer, err := NewPublicKey(parts[0])
+	if err != nil {
+		return Endorsement{}, err
+	}
+
+	signature, err := NewSignature(parts[1])
+	if err != nil {
+		return Endorsement{}, err
+	}
+
+	return Endorsement{signer, signature}, nil
+}
+
+func (endorsement Endorsement) String() string {
+	return endorsement.Signer.String() + "\n\n" + endorsement.Signature.String()
+}
+
+// EndorsementSet is an ordered set of ballotclerk endorsements, sorted by signer key
+// hash so that Ballot.String() is deterministic regardless of the order endorsements
+// were collected in.
+type EndorsementSet []Endorsement
+
+func NewEndorsementSet(rawEndorsementSet []byte) (EndorsementSet, error) {
+	rawEndorsements := bytes.Split(rawEndorsementSet, []byte("\n\n\n"))
+	if len(rawEndorsements) > maxEndorsements {
+		return nil, errors.New("Too many endorsements")
+	}
+
+	endorsementSet := make(EndorsementSet, len(rawEndorsements))
+	for i, rawEndorsement := range rawEndorsements {
+		endorsement, err := NewEndorsement(rawEndorsement)
+		if err != nil {
+			return nil, err
+		}
+		endorsementSet[i] = endorsement
+	}
+
+	sort.Slice(endorsementSet, func(i, j int) bool {
+		return endorsementSet[i].Signer.hash() < endorsementSet[j].Signer.hash()
+	})
+
+	return endorsementSet, nil
+}
+
+func (endorsementSet EndorsementSet) String() string {
+	strs := make([]string, len(endorsementSet))
+	for i, endorsement := range endorsementSet {
+		strs[i] = endorsement.String()
+	}
+	return strings.Join(strs, "\n\n\n")
+}
+
+// UserSet is the set of ballotclerk public-keys a deployment trusts to endorse
+// ballots, keyed by PublicKey.hash().
+type UserSet map[string]PublicKey
+
+// VerifyEndorsements checks that at least threshold distinct clerks in clerks have
+// validly endorsed this ballot. Endorsements from unrecognised signers, endorsements
+// with a bad signature, and duplicate endorsements from the same clerk do not count
+// toward the threshold.
+func (ballot Ballot) VerifyEndorsements(clerks UserSet, threshold int) error {
+	payload := []byte(strings.Join([]string{
+		ballot.ElectionID,
+		ballot.BallotID.String(),
+		ballot.PublicKey.String(),
+		ballot.Vote.String(),
+		ballot.TagSet.String(),
+	}, "\n\n"))
+
+	seen := make(map[string]bool)
+	valid := 0
+	for _, endorsement := range ballot.Endorsements {
+		hash := endorsement.Signer.hash()
+
+		if _, trusted := clerks[hash]; !trusted {
+			continue
+		}
+		if seen[hash] {
+			continue
+		}
+		if err := endorsement.Signature.VerifySignature(endorsement.Signer, payload); err != nil {
+			continue
+		}
+
+		seen[hash] = true
+		valid++
+	}
+
+	if valid < threshold {
+		return errors.New("Ballot does not have enough valid ballotclerk endorsements")
+	}
+	return nil
+}
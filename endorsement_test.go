@@ -0,0 +1,68 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"testing"
+)
+
+func TestVerifyEndorsementsThreshold(t *testing.T) {
+	raw := newTestEd25519Ballot(t, "election-1", "choice-a", "")
+	ballot, err := NewBallot(raw, nil)
+	if err != nil {
+		t.Fatalf("NewBallot: %v", err)
+	}
+	payload := signedPayload(ballot)
+
+	clerks := UserSet{}
+	var endorsements EndorsementSet
+	for i := 0; i < 2; i++ {
+		signer, priv := newTestClerk(t)
+		clerks[signer.hash()] = signer
+		sig := ed25519.Sign(priv, payload)
+		endorsements = append(endorsements, Endorsement{signer, Signature(sig)})
+	}
+	ballot.Endorsements = endorsements
+
+	if err := ballot.VerifyEndorsements(clerks, 2); err != nil {
+		t.Fatalf("expected 2 valid endorsements to satisfy threshold 2: %v", err)
+	}
+	if err := ballot.VerifyEndorsements(clerks, 3); err == nil {
+		t.Fatalf("expected threshold 3 to fail with only 2 endorsements")
+	}
+}
+
+func TestVerifyEndorsementsRejectsUntrustedSigner(t *testing.T) {
+	raw := newTestEd25519Ballot(t, "election-1", "choice-a", "")
+	ballot, err := NewBallot(raw, nil)
+	if err != nil {
+		t.Fatalf("NewBallot: %v", err)
+	}
+
+	signer, priv := newTestClerk(t)
+	sig := ed25519.Sign(priv, signedPayload(ballot))
+	ballot.Endorsements = EndorsementSet{{signer, Signature(sig)}}
+
+	if err := ballot.VerifyEndorsements(UserSet{}, 1); err == nil {
+		t.Fatalf("expected endorsement from an untrusted signer to be rejected")
+	}
+}
+
+func TestVerifyEndorsementsIgnoresDuplicateSigner(t *testing.T) {
+	raw := newTestEd25519Ballot(t, "election-1", "choice-a", "")
+	ballot, err := NewBallot(raw, nil)
+	if err != nil {
+		t.Fatalf("NewBallot: %v", err)
+	}
+
+	signer, priv := newTestClerk(t)
+	sig := ed25519.Sign(priv, signedPayload(ballot))
+	ballot.Endorsements = EndorsementSet{
+		{signer, Signature(sig)},
+		{signer, Signature(sig)},
+	}
+
+	clerks := UserSet{signer.hash(): signer}
+	if err := ballot.VerifyEndorsements(clerks, 2); err == nil {
+		t.Fatalf("expected duplicate endorsements from the same clerk not to count twice")
+	}
+}
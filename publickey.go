@@ -0,0 +1,126 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+)
+
+// SignatureAlgorithm identifies the scheme a PublicKey/Signature pair was negotiated for.
+type SignatureAlgorithm int
+
+const (
+	// RSAPKCS1v15SHA256 is RSA signing with PKCS#1 v1.5 padding over a SHA-256 digest.
+	RSAPKCS1v15SHA256 SignatureAlgorithm = iota
+	// RSAPSSSHA512 is RSA signing with PSS padding over a SHA-512 digest.
+	RSAPSSSHA512
+	// Ed25519 is EdDSA over Curve25519. Keys and signatures are fixed-size and much
+	// cheaper to verify than RSA, which matters when a ballotclerk is counting millions
+	// of ballots.
+	Ed25519
+)
+
+func (alg SignatureAlgorithm) String() string {
+	switch alg {
+	case RSAPKCS1v15SHA256:
+		return "RSA-PKCS1v15-SHA256"
+	case RSAPSSSHA512:
+		return "RSA-PSS-SHA512"
+	case Ed25519:
+		return "ED25519"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// digestSize returns the size, in bytes, of the digest used to derive a BallotID
+// under the given signature algorithm.
+func digestSize(alg SignatureAlgorithm) int {
+	switch alg {
+	case Ed25519:
+		return sha256DigestSize
+	default:
+		return sha512DigestSize
+	}
+}
+
+const (
+	sha256DigestSize = 32
+	sha512DigestSize = 64
+)
+
+// PublicKey is a base64 encoded PEM formatted public-key, negotiated to one of the
+// supported SignatureAlgorithms via its PEM block type.
+type PublicKey struct {
+	Algorithm SignatureAlgorithm
+	Key       interface{} // *rsa.PublicKey or ed25519.PublicKey
+	raw       []byte
+}
+
+func NewPublicKey(rawPublicKey []byte) (PublicKey, error) {
+	// The ballot field is base64 of a PEM formatted public-key, not raw PEM: PEM's
+	// own line breaks would otherwise collide with the "\n\n" field separator used
+	// to parse the rest of the ballot.
+	pemBytes := make([]byte, base64.StdEncoding.DecodedLen(len(rawPublicKey)))
+	n, err := base64.StdEncoding.Decode(pemBytes, rawPublicKey)
+	if err != nil {
+		return PublicKey{}, errors.New("Cannot read public-key. Must be base64 encoded")
+	}
+	pemBytes = pemBytes[:n]
+
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return PublicKey{}, errors.New("Cannot read public-key. Invalid PEM format")
+	}
+
+	switch block.Type {
+	case "PUBLIC KEY":
+		pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return PublicKey{}, errors.New("Cannot read public-key. " + err.Error())
+		}
+		rsaPub, ok := pub.(*rsa.PublicKey)
+		if !ok {
+			return PublicKey{}, errors.New("Cannot read public-key. Expected an RSA public-key")
+		}
+		return PublicKey{Algorithm: RSAPKCS1v15SHA256, Key: rsaPub, raw: rawPublicKey}, nil
+
+	case "RSA-PSS PUBLIC KEY":
+		pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return PublicKey{}, errors.New("Cannot read public-key. " + err.Error())
+		}
+		rsaPub, ok := pub.(*rsa.PublicKey)
+		if !ok {
+			return PublicKey{}, errors.New("Cannot read public-key. Expected an RSA public-key")
+		}
+		return PublicKey{Algorithm: RSAPSSSHA512, Key: rsaPub, raw: rawPublicKey}, nil
+
+	case "ED25519 PUBLIC KEY":
+		if len(block.Bytes) != ed25519.PublicKeySize {
+			return PublicKey{}, errors.New("Cannot read public-key. Invalid ed25519 public-key size")
+		}
+		return PublicKey{Algorithm: Ed25519, Key: ed25519.PublicKey(block.Bytes), raw: rawPublicKey}, nil
+
+	default:
+		return PublicKey{}, errors.New("Cannot read public-key. Unsupported PEM block type: " + block.Type)
+	}
+}
+
+func (pub PublicKey) String() string {
+	return string(pub.raw)
+}
+
+// hash returns the hex encoded SHA-256 digest of the public-key's base64 encoded PEM
+// field (the bytes String returns). It
+// is used to identify signers, e.g. when sorting an EndorsementSet or looking a clerk
+// up in a UserSet.
+func (pub PublicKey) hash() string {
+	digest := sha256.Sum256(pub.raw)
+	return hex.EncodeToString(digest[:])
+}
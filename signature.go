@@ -0,0 +1,70 @@
+package main
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+)
+
+// Signature is the raw crypto signature for a ballot; the text form carries it hex
+// encoded (see NewSignature and String). Its length and the
+// scheme used to verify it are determined by the PublicKey.Algorithm it is paired with.
+type Signature []byte
+
+func NewSignature(rawSignature []byte) (Signature, error) {
+	sig := make([]byte, hex.DecodedLen(len(rawSignature)))
+	n, err := hex.Decode(sig, rawSignature)
+	if err != nil {
+		return nil, errors.New("Cannot read signature. Signature must be hex encoded")
+	}
+	return Signature(sig[:n]), nil
+}
+
+func (sig Signature) String() string {
+	return hex.EncodeToString(sig)
+}
+
+// VerifySignature verifies that sig is a valid signature over data, using the
+// algorithm negotiated by pub.
+func (sig Signature) VerifySignature(pub PublicKey, data []byte) error {
+	switch pub.Algorithm {
+	case RSAPKCS1v15SHA256:
+		rsaPub, ok := pub.Key.(*rsa.PublicKey)
+		if !ok {
+			return errors.New("Cannot verify signature. Public-key is not an RSA key")
+		}
+		digest := sha256.Sum256(data)
+		if err := rsa.VerifyPKCS1v15(rsaPub, crypto.SHA256, digest[:], sig); err != nil {
+			return errors.New("Invalid signature")
+		}
+		return nil
+
+	case RSAPSSSHA512:
+		rsaPub, ok := pub.Key.(*rsa.PublicKey)
+		if !ok {
+			return errors.New("Cannot verify signature. Public-key is not an RSA key")
+		}
+		digest := sha512.Sum512(data)
+		if err := rsa.VerifyPSS(rsaPub, crypto.SHA512, digest[:], sig, nil); err != nil {
+			return errors.New("Invalid signature")
+		}
+		return nil
+
+	case Ed25519:
+		edPub, ok := pub.Key.(ed25519.PublicKey)
+		if !ok {
+			return errors.New("Cannot verify signature. Public-key is not an ed25519 key")
+		}
+		if !ed25519.Verify(edPub, data, sig) {
+			return errors.New("Invalid signature")
+		}
+		return nil
+
+	default:
+		return errors.New("Cannot verify signature. Unsupported signature algorithm")
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// tagKeyRegex mirrors the style of the ElectionID validator: tag keys may only
+// contain ASCII letters, digits, underscore, dot, and dash.
+var tagKeyRegex = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+type Tag struct {
+	Key   []byte
+	Value []byte
+}
+
+func NewTag(rawTag []byte) (Tag, error) {
+	parts := bytes.SplitN(rawTag, []byte("="), 2)
+	if len(parts) != 2 {
+		return Tag{}, errors.New("Malformed tag")
+	}
+	return newTag(parts[0], parts[1])
+}
+
+// newTag builds and validates a Tag from an already-split key/value pair, shared by
+// NewTag (parsing "key=value" text) and TagSet.Set (building one programmatically).
+func newTag(key, value []byte) (Tag, error) {
+	if len(key) > maxTagKeySize {
+		return Tag{}, errors.New("Tag key too long")
+	}
+	if len(value) > maxTagValueSize {
+		return Tag{}, errors.New("Tag value too long")
+	}
+	if !tagKeyRegex.Match(key) {
+		return Tag{}, errors.New("Tag key must match " + tagKeyRegex.String())
+	}
+	if bytes.ContainsRune(value, '\n') {
+		return Tag{}, errors.New("Tag value must not contain a newline")
+	}
+	return Tag{key, value}, nil
+}
+
+func (tag Tag) String() string {
+	return string(tag.Key) + "=" + string(tag.Value)
+}
+
+// TagSet is an ordered set of Tags, keyed by Tag.Key. Keys are unique: parsing or
+// setting a key that already exists is an error for NewTagSet and an overwrite for
+// Set, mirroring how a real ordered map behaves.
+type TagSet struct {
+	tags  []Tag
+	index map[string]int
+}
+
+func NewTagSet(rawTagSet []byte) (TagSet, error) {
+	tagSet := TagSet{index: make(map[string]int)}
+	if len(rawTagSet) == 0 {
+		return tagSet, nil
+	}
+	for _, rawTag := range bytes.Split(rawTagSet, []byte("\n")) {
+		tag, err := NewTag(rawTag)
+		if err != nil {
+			return TagSet{}, err
+		}
+		if err := tagSet.add(tag); err != nil {
+			return TagSet{}, err
+		}
+	}
+	return tagSet, nil
+}
+
+// add appends tag to the set, rejecting duplicate keys. Used when parsing untrusted
+// input, where a repeated key is treated as malformed rather than an update.
+func (tagSet *TagSet) add(tag Tag) error {
+	if tagSet.index == nil {
+		tagSet.index = make(map[string]int)
+	}
+	key := string(tag.Key)
+	if _, exists := tagSet.index[key]; exists {
+		return errors.New("Duplicate tag key: " + key)
+	}
+	tagSet.index[key] = len(tagSet.tags)
+	tagSet.tags = append(tagSet.tags, tag)
+	return nil
+}
+
+// Set adds a new tag or overwrites the value of an existing one.
+func (tagSet *TagSet) Set(key, value []byte) error {
+	tag, err := newTag(key, value)
+	if err != nil {
+		return err
+	}
+	if tagSet.index == nil {
+		tagSet.index = make(map[string]int)
+	}
+	if i, exists := tagSet.index[string(key)]; exists {
+		tagSet.tags[i] = tag
+		return nil
+	}
+	tagSet.index[string(key)] = len(tagSet.tags)
+	tagSet.tags = append(tagSet.tags, tag)
+	return nil
+}
+
+// Get returns the value for key and whether it was present.
+func (tagSet TagSet) Get(key string) ([]byte, bool) {
+	i, ok := tagSet.index[key]
+	if !ok {
+		return nil, false
+	}
+	return tagSet.tags[i].Value, true
+}
+
+// Has reports whether key is present in the set.
+func (tagSet TagSet) Has(key string) bool {
+	_, ok := tagSet.index[key]
+	return ok
+}
+
+func (tagSet TagSet) String() string {
+	strs := make([]string, len(tagSet.tags))
+	for i, tag := range tagSet.tags {
+		strs[i] = tag.String()
+	}
+	return strings.Join(strs, "\n")
+}
+
+// TagField describes the constraints a TagSchema places on a single tag key.
+type TagField struct {
+	Required   bool
+	ValueRegex *regexp.Regexp // nil permits any value that passes the base Tag validation
+}
+
+// TagSchema is a per-election declaration of which tags a ballot may carry, so a
+// ballotclerk can reject malformed or unexpected metadata at ingest time rather than
+// leaving it to whatever eventually counts the ballot.
+type TagSchema struct {
+	Fields map[string]TagField
+}
+
+// Validate checks tagSet against the schema: no key may appear that isn't declared,
+// declared values must match their ValueRegex (if any), and every Required field
+// must be present.
+func (schema TagSchema) Validate(tagSet TagSet) error {
+	for _, tag := range tagSet.tags {
+		key := string(tag.Key)
+		field, allowed := schema.Fields[key]
+		if !allowed {
+			return errors.New("Tag not permitted by election schema: " + key)
+		}
+		if field.ValueRegex != nil && !field.ValueRegex.Match(tag.Value) {
+			return errors.New("Tag value does not match election schema: " + key)
+		}
+	}
+	for key, field := range schema.Fields {
+		if field.Required && !tagSet.Has(key) {
+			return errors.New("Missing required tag: " + key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import "testing"
+
+func TestTagSetRejectsDuplicateKeys(t *testing.T) {
+	if _, err := NewTagSet([]byte("region=west\nregion=east")); err == nil {
+		t.Fatalf("expected duplicate tag key to be rejected")
+	}
+}
+
+func TestTagSetRejectsInvalidKey(t *testing.T) {
+	if _, err := NewTagSet([]byte("bad key=1")); err == nil {
+		t.Fatalf("expected tag key with a space to be rejected")
+	}
+}
+
+func TestTagSetGetSetHas(t *testing.T) {
+	tagSet, err := NewTagSet([]byte("region=west\nround=1"))
+	if err != nil {
+		t.Fatalf("NewTagSet: %v", err)
+	}
+
+	if !tagSet.Has("region") {
+		t.Fatalf("expected Has(region) to be true")
+	}
+	if val, ok := tagSet.Get("round"); !ok || string(val) != "1" {
+		t.Fatalf("Get(round) = %q, %v", val, ok)
+	}
+	if _, ok := tagSet.Get("missing"); ok {
+		t.Fatalf("expected Get(missing) to be false")
+	}
+
+	if err := tagSet.Set([]byte("region"), []byte("east")); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if val, _ := tagSet.Get("region"); string(val) != "east" {
+		t.Fatalf("expected Set to overwrite an existing key, got %q", val)
+	}
+}
+
+func TestTagSchemaValidate(t *testing.T) {
+	schema := TagSchema{Fields: map[string]TagField{
+		"region": {Required: true},
+	}}
+
+	tagSet, err := NewTagSet([]byte("region=west"))
+	if err != nil {
+		t.Fatalf("NewTagSet: %v", err)
+	}
+	if err := schema.Validate(tagSet); err != nil {
+		t.Fatalf("expected valid tagset to pass schema: %v", err)
+	}
+
+	if err := schema.Validate(TagSet{}); err == nil {
+		t.Fatalf("expected missing required tag to fail schema")
+	}
+
+	extra, err := NewTagSet([]byte("region=west\nother=1"))
+	if err != nil {
+		t.Fatalf("NewTagSet: %v", err)
+	}
+	if err := schema.Validate(extra); err == nil {
+		t.Fatalf("expected tag not declared by schema to be rejected")
+	}
+}
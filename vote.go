@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+)
+
+type Vote [][]byte // Ordered list of choices represented by git addresses
+
+// VoteMode determines how NewVote validates a ballot's vote items and how
+// Vote.Tally() interprets them.
+type VoteMode int
+
+const (
+	// Plurality votes carry exactly one choice.
+	Plurality VoteMode = iota
+	// Approval votes carry any subset of the choices, each endorsed equally, with no
+	// duplicates.
+	Approval
+	// Ranked votes list distinct choices, most to least preferred, with no duplicates
+	// and no more entries than the election has choices.
+	Ranked
+	// Score votes carry "choice=score" pairs, at most one per choice, with each score
+	// in [0, Election.MaxScore].
+	Score
+)
+
+// Election is the metadata a ballotclerk loads alongside a ballot to validate its
+// Vote and TagSet: the set of valid choices (git addresses, per the Vote doc
+// comment above), how a ballot may express a preference among them, and the tags
+// the election permits.
+type Election struct {
+	ID        string
+	Choices   []string
+	Mode      VoteMode
+	MaxScore  int // only consulted when Mode == Score
+	TagSchema *TagSchema
+}
+
+func (election Election) hasChoice(choice string) bool {
+	for _, c := range election.Choices {
+		if c == choice {
+			return true
+		}
+	}
+	return false
+}
+
+// NewVote parses a vote and, if election is non-nil, validates it against the
+// election's VoteMode: choices must exist in the election, and duplicates,
+// out-of-range scores, or too many ranked choices are rejected. A nil election
+// skips these election checks, e.g. when parsing a ballot whose election schema
+// isn't available yet; an empty vote item is rejected either way.
+func NewVote(rawVote []byte, election *Election) (Vote, error) {
+	vote := Vote(bytes.Split(rawVote, []byte("\n")))
+
+	if err := validateVote(vote, election); err != nil {
+		return nil, err
+	}
+
+	return vote, nil
+}
+
+// validateVote checks vote against election's VoteMode. A nil election skips the
+// mode-specific checks below (e.g. when parsing a ballot whose election schema isn't
+// available yet), but an empty vote item is rejected unconditionally: besides never
+// being a meaningful choice, it's also how a raw vote field ending in "\n" (or
+// containing "\n\n") would otherwise sneak past NewVote, letting a ballot's Vote field
+// forge a spurious "\n\n" that collides with the ballot format's own field separator.
+func validateVote(vote Vote, election *Election) error {
+	for _, item := range vote {
+		if len(item) == 0 {
+			return errors.New("Vote item cannot be empty")
+		}
+	}
+
+	if election == nil {
+		return nil
+	}
+
+	switch election.Mode {
+	case Plurality:
+		if len(vote) != 1 {
+			return errors.New("Plurality vote must have exactly one choice")
+		}
+		return requireKnownChoices(vote, election)
+
+	case Approval:
+		return requireKnownChoices(vote, election)
+
+	case Ranked:
+		if len(vote) > len(election.Choices) {
+			return errors.New("Ranked vote cannot have more choices than the election")
+		}
+		return requireKnownChoices(vote, election)
+
+	case Score:
+		seen := make(map[string]bool)
+		for _, item := range vote {
+			parts := bytes.SplitN(item, []byte("="), 2)
+			if len(parts) != 2 {
+				return errors.New("Malformed score vote item")
+			}
+			choice := string(parts[0])
+			if !election.hasChoice(choice) {
+				return errors.New("Vote references unknown choice: " + choice)
+			}
+			if seen[choice] {
+				return errors.New("Duplicate choice in score vote: " + choice)
+			}
+			seen[choice] = true
+
+			score, err := strconv.Atoi(string(parts[1]))
+			if err != nil || score < 0 || score > election.MaxScore {
+				return errors.New("Score out of range for choice: " + choice)
+			}
+		}
+		return nil
+
+	default:
+		return errors.New("Unknown vote mode")
+	}
+}
+
+// requireKnownChoices rejects a vote referencing a choice outside the election, or
+// listing the same choice twice. It's shared by the Plurality, Approval, and Ranked
+// modes, which all just need "every item is a distinct, valid choice".
+func requireKnownChoices(vote Vote, election *Election) error {
+	seen := make(map[string]bool)
+	for _, item := range vote {
+		choice := string(item)
+		if !election.hasChoice(choice) {
+			return errors.New("Vote references unknown choice: " + choice)
+		}
+		if seen[choice] {
+			return errors.New("Duplicate choice in vote: " + choice)
+		}
+		seen[choice] = true
+	}
+	return nil
+}
+
+func (vote Vote) String() string {
+	var output string
+	for i, voteItem := range vote {
+		output += string(voteItem)
+		if i != len(vote)-1 {
+			output += "\n"
+		}
+	}
+	return output
+}
+
+// Tally returns this vote's contribution to each choice under mode, so a counting
+// service can sum contributions across many ballots with TallyVotes. Plurality and
+// Approval each contribute 1 per chosen item; Ranked contributes a Borda-style
+// descending weight; Score contributes the given score.
+func (vote Vote) Tally(mode VoteMode) map[string]int {
+	contribution := make(map[string]int)
+
+	switch mode {
+	case Plurality, Approval:
+		for _, item := range vote {
+			contribution[string(item)]++
+		}
+
+	case Ranked:
+		for i, item := range vote {
+			contribution[string(item)] += len(vote) - i
+		}
+
+	case Score:
+		for _, item := range vote {
+			parts := bytes.SplitN(item, []byte("="), 2)
+			if len(parts) != 2 {
+				continue
+			}
+			score, err := strconv.Atoi(string(parts[1]))
+			if err != nil {
+				continue
+			}
+			contribution[string(parts[0])] += score
+		}
+	}
+
+	return contribution
+}
+
+// TallyVotes sums the Tally of every vote under mode, giving each choice's total
+// score across a ballotbox.
+func TallyVotes(votes []Vote, mode VoteMode) map[string]int {
+	totals := make(map[string]int)
+	for _, vote := range votes {
+		for choice, weight := range vote.Tally(mode) {
+			totals[choice] += weight
+		}
+	}
+	return totals
+}
@@ -0,0 +1,70 @@
+package main
+
+import "testing"
+
+func TestNewVoteModes(t *testing.T) {
+	cases := []struct {
+		name    string
+		mode    VoteMode
+		raw     string
+		wantErr bool
+	}{
+		{"plurality valid", Plurality, "a", false},
+		{"plurality multiple choices", Plurality, "a\nb", true},
+		{"plurality unknown choice", Plurality, "z", true},
+
+		{"approval valid", Approval, "a\nb", false},
+		{"approval duplicate", Approval, "a\na", true},
+		{"approval unknown choice", Approval, "z", true},
+
+		{"ranked valid", Ranked, "b\na", false},
+		{"ranked too many choices", Ranked, "a\nb\nc\na", true},
+		{"ranked duplicate", Ranked, "a\na", true},
+
+		{"score valid", Score, "a=5\nb=1", false},
+		{"score out of range", Score, "a=999", true},
+		{"score malformed", Score, "a", true},
+		{"score duplicate", Score, "a=1\na=2", true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			election := &Election{Choices: []string{"a", "b", "c"}, Mode: c.mode, MaxScore: 10}
+			_, err := NewVote([]byte(c.raw), election)
+			if c.wantErr && err == nil {
+				t.Fatalf("expected error, got nil")
+			}
+			if !c.wantErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestNewVoteSkipsValidationWithNilElection(t *testing.T) {
+	if _, err := NewVote([]byte("anything"), nil); err != nil {
+		t.Fatalf("expected nil election to skip validation, got: %v", err)
+	}
+}
+
+func TestTallyVotes(t *testing.T) {
+	votes := []Vote{
+		{[]byte("a")},
+		{[]byte("a")},
+		{[]byte("b")},
+	}
+	totals := TallyVotes(votes, Plurality)
+	if totals["a"] != 2 || totals["b"] != 1 {
+		t.Fatalf("unexpected totals: %+v", totals)
+	}
+}
+
+func TestTallyVotesRanked(t *testing.T) {
+	votes := []Vote{
+		{[]byte("a"), []byte("b")},
+	}
+	totals := TallyVotes(votes, Ranked)
+	if totals["a"] != 2 || totals["b"] != 1 {
+		t.Fatalf("unexpected ranked totals: %+v", totals)
+	}
+}